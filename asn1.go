@@ -0,0 +1,233 @@
+package paillier
+
+import (
+	"crypto/sha256"
+	"encoding/asn1"
+	"encoding/pem"
+	"errors"
+	"math/big"
+)
+
+// PublicKey ASN.1 schema:
+//
+//	PaillierPublicKey ::= SEQUENCE {
+//	    version INTEGER,
+//	    n       INTEGER,
+//	    g       INTEGER
+//	}
+type publicKeyASN1 struct {
+	Version int
+	N       *big.Int
+	G       *big.Int
+}
+
+// PrivateKey ASN.1 schema. P and Q are optional: they are only present when
+// the key was (or can be) precomputed for CRT decryption, see Precompute.
+//
+//	PaillierPrivateKey ::= SEQUENCE {
+//	    version INTEGER,
+//	    n       INTEGER,
+//	    lambda  INTEGER,
+//	    mu      INTEGER,
+//	    p       INTEGER OPTIONAL,
+//	    q       INTEGER OPTIONAL
+//	}
+type privateKeyASN1 struct {
+	Version int
+	N       *big.Int
+	Lambda  *big.Int
+	Mu      *big.Int
+	P       *big.Int `asn1:"optional"`
+	Q       *big.Int `asn1:"optional"`
+}
+
+// Ciphertext ASN.1 schema. KeyOID ties the ciphertext to the key it was
+// produced under so that mixing ciphertexts from unrelated keys is rejected
+// rather than silently producing garbage, see WrapCiphertext.
+//
+//	PaillierCiphertext ::= SEQUENCE {
+//	    keyOID OBJECT IDENTIFIER,
+//	    c      INTEGER
+//	}
+type ciphertextASN1 struct {
+	KeyOID asn1.ObjectIdentifier
+	C      *big.Int
+}
+
+const asn1Version = 1
+
+// Ciphertext pairs a raw Paillier ciphertext with the object identifier of
+// the key it was encrypted under, so that homomorphic operations can detect
+// and reject ciphertexts produced under a different key.
+type Ciphertext struct {
+	C      *big.Int
+	KeyOID asn1.ObjectIdentifier
+}
+
+// keyOIDArcBits is the widest an individual OID arc can be while still
+// round-tripping through encoding/asn1: its base-128 decoder rejects any
+// arc whose value exceeds math.MaxInt32, regardless of the native int's
+// width.
+const keyOIDArcBits = 31
+
+// KeyOID returns an object identifier derived from pk.N, under the
+// "UUID arc" 2.25 (ITU-T X.667). Unlike a true UUID arc this is spread
+// across four 31-bit arcs (124 of the digest's 256 bits) rather than the
+// full 128, since encoding/asn1 rejects a single arc that doesn't fit in
+// an int32 (see keyOIDArcBits); splitting across several arcs is the most
+// entropy that's safely portable. It is deterministic and
+// collision-resistant enough that distinct keys are vanishingly unlikely
+// to collide, even across very large fleets, but it is not a registered
+// or globally unique identifier.
+func (pk *PublicKey) KeyOID() asn1.ObjectIdentifier {
+	sum := sha256.Sum256(pk.N.Bytes())
+	oid := asn1.ObjectIdentifier{2, 25}
+	bits := new(big.Int).SetBytes(sum[:])
+	mask := new(big.Int).Sub(new(big.Int).Lsh(one, keyOIDArcBits), one)
+	arc := new(big.Int)
+	for i := 0; i < 4; i++ {
+		arc.And(bits, mask)
+		oid = append(oid, int(arc.Int64()))
+		bits.Rsh(bits, keyOIDArcBits)
+	}
+	return oid
+}
+
+// WrapCiphertext tags a raw ciphertext produced by pk with pk's KeyOID, so
+// it can later be safely combined with other wrapped ciphertexts via
+// PublicKey.AddCiphertexts.
+func (pk *PublicKey) WrapCiphertext(c *big.Int) *Ciphertext {
+	return &Ciphertext{C: c, KeyOID: pk.KeyOID()}
+}
+
+// AddCiphertexts is the Ciphertext-aware counterpart to Add: it rejects
+// ciphertexts that were not produced under pk before combining them.
+func (pk *PublicKey) AddCiphertexts(ct1, ct2 *Ciphertext) (*Ciphertext, error) {
+	oid := pk.KeyOID()
+	if !ct1.KeyOID.Equal(oid) || !ct2.KeyOID.Equal(oid) {
+		return nil, errors.New("paillier: ciphertexts were encrypted under different keys")
+	}
+	sum, err := pk.Add(ct1.C, ct2.C)
+	if err != nil {
+		return nil, err
+	}
+	return &Ciphertext{C: sum, KeyOID: oid}, nil
+}
+
+// MarshalASN1 encodes pk per the PaillierPublicKey schema.
+func (pk *PublicKey) MarshalASN1() ([]byte, error) {
+	return asn1.Marshal(publicKeyASN1{Version: asn1Version, N: pk.N, G: pk.G})
+}
+
+// ParsePublicKeyASN1 decodes a PaillierPublicKey produced by MarshalASN1.
+func ParsePublicKeyASN1(der []byte) (*PublicKey, error) {
+	var k publicKeyASN1
+	if rest, err := asn1.Unmarshal(der, &k); err != nil {
+		return nil, err
+	} else if len(rest) != 0 {
+		return nil, errors.New("paillier: trailing data after ASN.1 public key")
+	}
+	return &PublicKey{N: k.N, G: k.G, N2: new(big.Int).Mul(k.N, k.N)}, nil
+}
+
+// MarshalPEM encodes pk as a "PAILLIER PUBLIC KEY" PEM block.
+func (pk *PublicKey) MarshalPEM() ([]byte, error) {
+	der, err := pk.MarshalASN1()
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PAILLIER PUBLIC KEY", Bytes: der}), nil
+}
+
+// ParsePublicKeyPEM decodes a PEM block produced by PublicKey.MarshalPEM.
+func ParsePublicKeyPEM(data []byte) (*PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != "PAILLIER PUBLIC KEY" {
+		return nil, errors.New("paillier: not a PAILLIER PUBLIC KEY PEM block")
+	}
+	return ParsePublicKeyASN1(block.Bytes)
+}
+
+// MarshalASN1 encodes sk per the PaillierPrivateKey schema. P and Q are
+// included so that ParsePrivateKeyASN1 can reconstruct a key ready for
+// Precompute without regenerating it.
+func (sk *PrivateKey) MarshalASN1() ([]byte, error) {
+	return asn1.Marshal(privateKeyASN1{
+		Version: asn1Version,
+		N:       sk.N,
+		Lambda:  sk.Lambda,
+		Mu:      sk.Mu,
+		P:       sk.p,
+		Q:       sk.q,
+	})
+}
+
+// ParsePrivateKeyASN1 decodes a PaillierPrivateKey produced by MarshalASN1.
+func ParsePrivateKeyASN1(der []byte) (*PrivateKey, error) {
+	var k privateKeyASN1
+	if rest, err := asn1.Unmarshal(der, &k); err != nil {
+		return nil, err
+	} else if len(rest) != 0 {
+		return nil, errors.New("paillier: trailing data after ASN.1 private key")
+	}
+	pk := PublicKey{N: k.N, G: new(big.Int).Add(k.N, one), N2: new(big.Int).Mul(k.N, k.N)}
+	return &PrivateKey{
+		PublicKey: pk,
+		Lambda:    k.Lambda,
+		Mu:        k.Mu,
+		p:         k.P,
+		q:         k.Q,
+	}, nil
+}
+
+// MarshalPEM encodes sk as a "PAILLIER PRIVATE KEY" PEM block.
+func (sk *PrivateKey) MarshalPEM() ([]byte, error) {
+	der, err := sk.MarshalASN1()
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PAILLIER PRIVATE KEY", Bytes: der}), nil
+}
+
+// ParsePrivateKeyPEM decodes a PEM block produced by PrivateKey.MarshalPEM.
+func ParsePrivateKeyPEM(data []byte) (*PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != "PAILLIER PRIVATE KEY" {
+		return nil, errors.New("paillier: not a PAILLIER PRIVATE KEY PEM block")
+	}
+	return ParsePrivateKeyASN1(block.Bytes)
+}
+
+// MarshalASN1 encodes ct per the PaillierCiphertext schema.
+func (ct *Ciphertext) MarshalASN1() ([]byte, error) {
+	return asn1.Marshal(ciphertextASN1{KeyOID: ct.KeyOID, C: ct.C})
+}
+
+// ParseCiphertextASN1 decodes a PaillierCiphertext produced by MarshalASN1.
+func ParseCiphertextASN1(der []byte) (*Ciphertext, error) {
+	var c ciphertextASN1
+	if rest, err := asn1.Unmarshal(der, &c); err != nil {
+		return nil, err
+	} else if len(rest) != 0 {
+		return nil, errors.New("paillier: trailing data after ASN.1 ciphertext")
+	}
+	return &Ciphertext{C: c.C, KeyOID: c.KeyOID}, nil
+}
+
+// MarshalPEM encodes ct as a "PAILLIER CIPHERTEXT" PEM block.
+func (ct *Ciphertext) MarshalPEM() ([]byte, error) {
+	der, err := ct.MarshalASN1()
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PAILLIER CIPHERTEXT", Bytes: der}), nil
+}
+
+// ParseCiphertextPEM decodes a PEM block produced by Ciphertext.MarshalPEM.
+func ParseCiphertextPEM(data []byte) (*Ciphertext, error) {
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != "PAILLIER CIPHERTEXT" {
+		return nil, errors.New("paillier: not a PAILLIER CIPHERTEXT PEM block")
+	}
+	return ParseCiphertextASN1(block.Bytes)
+}
@@ -0,0 +1,152 @@
+package paillier
+
+import (
+	"encoding/asn1"
+	"testing"
+)
+
+func TestPublicKey_ASN1RoundTrip(t *testing.T) {
+	pk, _, err := GenerateKeyPair(1024)
+	if err != nil {
+		t.Fatalf("GenerateKeyPair() error = %v", err)
+	}
+
+	der, err := pk.MarshalASN1()
+	if err != nil {
+		t.Fatalf("MarshalASN1() error = %v", err)
+	}
+	got, err := ParsePublicKeyASN1(der)
+	if err != nil {
+		t.Fatalf("ParsePublicKeyASN1() error = %v", err)
+	}
+	if got.N.Cmp(pk.N) != 0 || got.G.Cmp(pk.G) != 0 || got.N2.Cmp(pk.N2) != 0 {
+		t.Errorf("ParsePublicKeyASN1() round-trip mismatch: got %+v, want %+v", got, pk)
+	}
+
+	pemBytes, err := pk.MarshalPEM()
+	if err != nil {
+		t.Fatalf("MarshalPEM() error = %v", err)
+	}
+	gotPEM, err := ParsePublicKeyPEM(pemBytes)
+	if err != nil {
+		t.Fatalf("ParsePublicKeyPEM() error = %v", err)
+	}
+	if gotPEM.N.Cmp(pk.N) != 0 {
+		t.Errorf("ParsePublicKeyPEM() round-trip mismatch")
+	}
+}
+
+func TestPrivateKey_ASN1RoundTrip(t *testing.T) {
+	pk, sk, err := GenerateKeyPair(1024)
+	if err != nil {
+		t.Fatalf("GenerateKeyPair() error = %v", err)
+	}
+
+	der, err := sk.MarshalASN1()
+	if err != nil {
+		t.Fatalf("MarshalASN1() error = %v", err)
+	}
+	got, err := ParsePrivateKeyASN1(der)
+	if err != nil {
+		t.Fatalf("ParsePrivateKeyASN1() error = %v", err)
+	}
+
+	ct, err := pk.Encrypt(42)
+	if err != nil {
+		t.Fatalf("Encrypt() error = %v", err)
+	}
+	m, err := got.Decrypt(ct)
+	if err != nil {
+		t.Fatalf("Decrypt() error = %v", err)
+	}
+	if m != 42 {
+		t.Errorf("Decrypt() after ASN.1 round-trip = %v, want 42", m)
+	}
+
+	if err := got.Precompute(); err != nil {
+		t.Fatalf("Precompute() error = %v", err)
+	}
+	if m, err := got.Decrypt(ct); err != nil || m != 42 {
+		t.Errorf("Decrypt() after Precompute on round-tripped key = %v, %v, want 42, nil", m, err)
+	}
+}
+
+// TestPrivateKey_ASN1RoundTripWithoutPQ covers a private key DER-encoded
+// without the optional P and Q fields (e.g. produced by a party that never
+// had them, or chose not to export them): Decrypt must still work, and
+// Precompute must report an error rather than panic, since there is no p
+// and q to derive CRT material from.
+func TestPrivateKey_ASN1RoundTripWithoutPQ(t *testing.T) {
+	pk, sk, err := GenerateKeyPair(1024)
+	if err != nil {
+		t.Fatalf("GenerateKeyPair() error = %v", err)
+	}
+
+	der, err := asn1.Marshal(privateKeyASN1{
+		Version: asn1Version,
+		N:       sk.N,
+		Lambda:  sk.Lambda,
+		Mu:      sk.Mu,
+	})
+	if err != nil {
+		t.Fatalf("marshal error = %v", err)
+	}
+	got, err := ParsePrivateKeyASN1(der)
+	if err != nil {
+		t.Fatalf("ParsePrivateKeyASN1() error = %v", err)
+	}
+
+	ct, err := pk.Encrypt(42)
+	if err != nil {
+		t.Fatalf("Encrypt() error = %v", err)
+	}
+	if m, err := got.Decrypt(ct); err != nil || m != 42 {
+		t.Errorf("Decrypt() without p/q = %v, %v, want 42, nil", m, err)
+	}
+
+	if err := got.Precompute(); err == nil {
+		t.Error("Precompute() without p/q did not return an error")
+	}
+}
+
+func TestCiphertext_ASN1RoundTripAndKeyMismatch(t *testing.T) {
+	pk1, _, err := GenerateKeyPair(1024)
+	if err != nil {
+		t.Fatalf("GenerateKeyPair() error = %v", err)
+	}
+	pk2, _, err := GenerateKeyPair(1024)
+	if err != nil {
+		t.Fatalf("GenerateKeyPair() error = %v", err)
+	}
+
+	raw, err := pk1.Encrypt(7)
+	if err != nil {
+		t.Fatalf("Encrypt() error = %v", err)
+	}
+	ct := pk1.WrapCiphertext(raw)
+
+	der, err := ct.MarshalASN1()
+	if err != nil {
+		t.Fatalf("MarshalASN1() error = %v", err)
+	}
+	got, err := ParseCiphertextASN1(der)
+	if err != nil {
+		t.Fatalf("ParseCiphertextASN1() error = %v", err)
+	}
+	if got.C.Cmp(ct.C) != 0 || !got.KeyOID.Equal(ct.KeyOID) {
+		t.Errorf("ParseCiphertextASN1() round-trip mismatch")
+	}
+
+	other, err := pk1.Encrypt(9)
+	if err != nil {
+		t.Fatalf("Encrypt() error = %v", err)
+	}
+	if _, err := pk1.AddCiphertexts(ct, pk1.WrapCiphertext(other)); err != nil {
+		t.Errorf("AddCiphertexts() with matching keys returned error: %v", err)
+	}
+
+	mismatched := pk2.WrapCiphertext(other)
+	if _, err := pk1.AddCiphertexts(ct, mismatched); err == nil {
+		t.Errorf("AddCiphertexts() with mismatched keys did not return an error")
+	}
+}
@@ -0,0 +1,136 @@
+package paillier
+
+import (
+	"crypto/rand"
+	"errors"
+	"math"
+	"math/big"
+)
+
+// DefaultScale is the number of fractional decimal digits EncryptFloat and
+// DecryptFloat preserve when no other scale has been established.
+const DefaultScale = 6
+
+// Encoder converts signed decimal values to and from fixed-point integers:
+// a value v is represented as round(v * 10^Scale). EncryptSigned then maps
+// such an integer onto Z_N (rejecting values outside [-N/2, N/2]), so that
+// negative values and fractional values survive a round trip.
+type Encoder struct {
+	Scale int
+}
+
+// NewEncoder returns an Encoder that preserves scale fractional decimal
+// digits.
+func NewEncoder(scale int) *Encoder {
+	return &Encoder{Scale: scale}
+}
+
+// Encode converts f to its fixed-point integer representation.
+func (e *Encoder) Encode(f float64) *big.Int {
+	scaled := f * math.Pow10(e.Scale)
+	return big.NewInt(int64(math.Round(scaled)))
+}
+
+// Decode converts a fixed-point integer representation back to a float64
+// at the Encoder's scale.
+func (e *Encoder) Decode(v *big.Int) float64 {
+	return float64(v.Int64()) / math.Pow10(e.Scale)
+}
+
+// encodeSignedToN maps v, which must satisfy -N/2 <= v <= N/2, onto Z_N by
+// adding N to negative values.
+func encodeSignedToN(v, n *big.Int) *big.Int {
+	if v.Sign() < 0 {
+		return new(big.Int).Add(v, n)
+	}
+	return new(big.Int).Mod(v, n)
+}
+
+// decodeSignedFromN is the inverse of encodeSignedToN: values in the upper
+// half of Z_N are interpreted as negative.
+func decodeSignedFromN(v, n *big.Int) *big.Int {
+	half := new(big.Int).Rsh(n, 1)
+	if v.Cmp(half) > 0 {
+		return new(big.Int).Sub(v, n)
+	}
+	return new(big.Int).Set(v)
+}
+
+// EncryptSigned encrypts a possibly-negative integer v, which must satisfy
+// -N/2 <= v <= N/2. Add, Sub and the other homomorphic operations on the
+// resulting ciphertext decrypt back to the true signed result via
+// DecryptSigned anywhere in that range, whereas Decrypt only recovers
+// results of small magnitude (see ratReconstruct).
+func (pk *PublicKey) EncryptSigned(v *big.Int) (*big.Int, error) {
+	half := new(big.Int).Rsh(pk.N, 1)
+	if new(big.Int).Abs(v).Cmp(half) > 0 {
+		return nil, errors.New("paillier: signed plaintext out of range [-n/2, n/2]")
+	}
+	ct, _, err := pk.encrypt(encodeSignedToN(v, pk.N), rand.Reader)
+	return ct, err
+}
+
+// DecryptSigned decrypts a ciphertext produced via EncryptSigned (or via
+// homomorphic operations on such ciphertexts), returning the signed result.
+func (sk *PrivateKey) DecryptSigned(ct *big.Int) (*big.Int, error) {
+	if !validCiphertext(ct, sk.N2) {
+		return nil, errors.New("paillier: invalid ciphertext")
+	}
+	return decodeSignedFromN(sk.decrypt(ct), sk.N), nil
+}
+
+// FixedPoint is a ciphertext encoding a fixed-point decimal value, along
+// with the scale needed to interpret it. MulFixed accumulates Scale as
+// ciphertexts are homomorphically multiplied by plaintext weights, so a
+// chain of weighted sums can be decoded correctly at the end.
+type FixedPoint struct {
+	C     *big.Int
+	Scale int
+}
+
+// EncryptFloat encrypts f as a fixed-point value at DefaultScale.
+func (pk *PublicKey) EncryptFloat(f float64) (*FixedPoint, error) {
+	enc := NewEncoder(DefaultScale)
+	c, err := pk.EncryptSigned(enc.Encode(f))
+	if err != nil {
+		return nil, err
+	}
+	return &FixedPoint{C: c, Scale: DefaultScale}, nil
+}
+
+// DecryptFloat decrypts a FixedPoint produced by EncryptFloat or MulFixed.
+func (sk *PrivateKey) DecryptFloat(fp *FixedPoint) (float64, error) {
+	m, err := sk.DecryptSigned(fp.C)
+	if err != nil {
+		return 0, err
+	}
+	return NewEncoder(fp.Scale).Decode(m), nil
+}
+
+// MulFixed homomorphically multiplies a FixedPoint ciphertext by a known
+// plaintext weight, encoding weight at the same scale as fp. The returned
+// FixedPoint's Scale is the sum of the two (fp.Scale*2, since weight is
+// encoded at fp.Scale), so DecryptFloat continues to decode correctly
+// however many weighted terms are chained together before decryption.
+func (pk *PublicKey) MulFixed(fp *FixedPoint, weight float64) (*FixedPoint, error) {
+	enc := NewEncoder(fp.Scale)
+	w := enc.Encode(weight)
+	c, err := pk.MultPlaintext(fp.C, w.Int64())
+	if err != nil {
+		return nil, err
+	}
+	return &FixedPoint{C: c, Scale: fp.Scale * 2}, nil
+}
+
+// AddFixed homomorphically adds two FixedPoint ciphertexts encoded at the
+// same scale, as produced by a chain of EncryptFloat/MulFixed calls.
+func (pk *PublicKey) AddFixed(a, b *FixedPoint) (*FixedPoint, error) {
+	if a.Scale != b.Scale {
+		return nil, errors.New("paillier: cannot add FixedPoint values with different scales")
+	}
+	c, err := pk.Add(a.C, b.C)
+	if err != nil {
+		return nil, err
+	}
+	return &FixedPoint{C: c, Scale: a.Scale}, nil
+}
@@ -0,0 +1,146 @@
+package paillier
+
+import (
+	"math"
+	"math/big"
+	"testing"
+)
+
+func TestPublicKey_SubNegativeResult(t *testing.T) {
+	pk, sk, err := GenerateKeyPair(1024)
+	if err != nil {
+		t.Fatalf("GenerateKeyPair() error = %v", err)
+	}
+
+	c2, err := pk.EncryptSigned(big.NewInt(2))
+	if err != nil {
+		t.Fatalf("EncryptSigned() error = %v", err)
+	}
+	c5, err := pk.EncryptSigned(big.NewInt(5))
+	if err != nil {
+		t.Fatalf("EncryptSigned() error = %v", err)
+	}
+
+	diff := pk.Sub(c2, c5)
+	got, err := sk.DecryptSigned(diff)
+	if err != nil {
+		t.Fatalf("DecryptSigned() error = %v", err)
+	}
+	if got.Cmp(big.NewInt(-3)) != 0 {
+		t.Errorf("DecryptSigned(Sub(2,5)) = %v, want -3", got)
+	}
+}
+
+func TestPublicKey_EncryptSignedRoundTrip(t *testing.T) {
+	pk, sk, err := GenerateKeyPair(1024)
+	if err != nil {
+		t.Fatalf("GenerateKeyPair() error = %v", err)
+	}
+
+	for _, v := range []int64{0, 1, -1, 12345, -98765} {
+		ct, err := pk.EncryptSigned(big.NewInt(v))
+		if err != nil {
+			t.Fatalf("EncryptSigned(%d) error = %v", v, err)
+		}
+		m, err := sk.DecryptSigned(ct)
+		if err != nil {
+			t.Fatalf("DecryptSigned() error = %v", err)
+		}
+		if m.Int64() != v {
+			t.Errorf("DecryptSigned(EncryptSigned(%d)) = %v, want %d", v, m, v)
+		}
+	}
+}
+
+func TestPublicKey_EncryptSignedBoundary(t *testing.T) {
+	pk, sk, err := GenerateKeyPair(1024)
+	if err != nil {
+		t.Fatalf("GenerateKeyPair() error = %v", err)
+	}
+
+	half := new(big.Int).Rsh(pk.N, 1)
+	negHalf := new(big.Int).Neg(half)
+
+	for _, v := range []*big.Int{half, negHalf} {
+		ct, err := pk.EncryptSigned(v)
+		if err != nil {
+			t.Fatalf("EncryptSigned(%v) error = %v", v, err)
+		}
+		m, err := sk.DecryptSigned(ct)
+		if err != nil {
+			t.Fatalf("DecryptSigned() error = %v", err)
+		}
+		if m.Cmp(v) != 0 {
+			t.Errorf("DecryptSigned(EncryptSigned(%v)) = %v, want %v", v, m, v)
+		}
+	}
+
+	justOutside := new(big.Int).Add(half, one)
+	if _, err := pk.EncryptSigned(justOutside); err == nil {
+		t.Errorf("EncryptSigned(%v) did not return an error", justOutside)
+	}
+}
+
+func TestPublicKey_EncryptFloatRoundTrip(t *testing.T) {
+	pk, sk, err := GenerateKeyPair(1024)
+	if err != nil {
+		t.Fatalf("GenerateKeyPair() error = %v", err)
+	}
+
+	for _, f := range []float64{0, 3.5, -2.25, 100.125} {
+		fp, err := pk.EncryptFloat(f)
+		if err != nil {
+			t.Fatalf("EncryptFloat(%v) error = %v", f, err)
+		}
+		got, err := sk.DecryptFloat(fp)
+		if err != nil {
+			t.Fatalf("DecryptFloat() error = %v", err)
+		}
+		if math.Abs(got-f) > 1e-6 {
+			t.Errorf("DecryptFloat(EncryptFloat(%v)) = %v", f, got)
+		}
+	}
+}
+
+func TestPublicKey_MulFixedWeightedSum(t *testing.T) {
+	pk, sk, err := GenerateKeyPair(1024)
+	if err != nil {
+		t.Fatalf("GenerateKeyPair() error = %v", err)
+	}
+
+	readings := []float64{10.5, 20.25, 30.0}
+	weights := []float64{0.5, 0.25, 0.25}
+
+	var sum *FixedPoint
+	for i, r := range readings {
+		enc, err := pk.EncryptFloat(r)
+		if err != nil {
+			t.Fatalf("EncryptFloat() error = %v", err)
+		}
+		weighted, err := pk.MulFixed(enc, weights[i])
+		if err != nil {
+			t.Fatalf("MulFixed() error = %v", err)
+		}
+		if sum == nil {
+			sum = weighted
+			continue
+		}
+		sum, err = pk.AddFixed(sum, weighted)
+		if err != nil {
+			t.Fatalf("AddFixed() error = %v", err)
+		}
+	}
+
+	got, err := sk.DecryptFloat(sum)
+	if err != nil {
+		t.Fatalf("DecryptFloat() error = %v", err)
+	}
+
+	var want float64
+	for i, r := range readings {
+		want += r * weights[i]
+	}
+	if math.Abs(got-want) > 1e-6 {
+		t.Errorf("weighted sum = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,381 @@
+// Package paillier implements the Paillier cryptosystem, a partially
+// homomorphic public-key encryption scheme that supports addition of
+// ciphertexts and multiplication of a ciphertext by a plaintext constant.
+package paillier
+
+import (
+	"crypto/rand"
+	"errors"
+	"io"
+	"math/big"
+	"sync"
+)
+
+var (
+	zero = big.NewInt(0)
+	one  = big.NewInt(1)
+)
+
+// PublicKey holds the public parameters of a Paillier key pair. Encryption
+// and the homomorphic operations on ciphertexts only require these values.
+type PublicKey struct {
+	N  *big.Int // modulus, N = P*Q
+	G  *big.Int // generator, G = N+1
+	N2 *big.Int // N^2, cached since every operation works modulo it
+}
+
+// PrivateKey extends PublicKey with the material required to decrypt.
+type PrivateKey struct {
+	PublicKey
+	Lambda *big.Int // lcm(P-1, Q-1)
+	Mu     *big.Int // (L(G^Lambda mod N^2))^-1 mod N
+
+	// p and q are retained from key generation so that decryption can
+	// later be accelerated via CRT, see Precompute.
+	p, q *big.Int
+
+	crtMu sync.RWMutex
+	crt   *crtPrecomputed
+}
+
+// crtPrecomputed holds the CRT decryption material computed by Precompute.
+// Mirrors the p/q-based speedup crypto/rsa applies to RSA decryption: by
+// working modulo p^2 and q^2 separately and recombining, Decrypt avoids a
+// single expensive exponentiation modulo N^2.
+type crtPrecomputed struct {
+	pp, qq   *big.Int // p^2, q^2
+	lp, lq   *big.Int // p-1, q-1
+	hp, hq   *big.Int // L(g^lp mod p^2)^-1 mod p, and the q analogue
+	qInvModP *big.Int // q^-1 mod p
+}
+
+// Precompute derives and caches the CRT decryption material for sk. It is
+// safe to call concurrently and redundant calls are cheap no-ops, so callers
+// on a decryption-heavy path (e.g. tallying many ciphertexts) can call it
+// lazily on first use rather than paying the cost for keys that are only
+// ever used for encryption.
+//
+// It returns an error if sk was not generated by GenerateKeyPair (or a
+// similar constructor) and so lacks the p and q needed for the CRT speedup
+// — for example a PrivateKey parsed from ASN.1/PEM data that omitted the
+// optional P and Q fields. Decrypt still works on such a key; it simply
+// cannot use the CRT path.
+func (sk *PrivateKey) Precompute() error {
+	sk.crtMu.RLock()
+	if sk.crt != nil {
+		sk.crtMu.RUnlock()
+		return nil
+	}
+	sk.crtMu.RUnlock()
+
+	sk.crtMu.Lock()
+	defer sk.crtMu.Unlock()
+	if sk.crt != nil {
+		return nil
+	}
+
+	p, q := sk.p, sk.q
+	if p == nil || q == nil {
+		return errors.New("paillier: cannot precompute CRT material without p and q")
+	}
+	pp := new(big.Int).Mul(p, p)
+	qq := new(big.Int).Mul(q, q)
+	lp := new(big.Int).Sub(p, one)
+	lq := new(big.Int).Sub(q, one)
+
+	gp := new(big.Int).Mod(sk.G, pp)
+	gp.Exp(gp, lp, pp)
+	hp := new(big.Int).ModInverse(lFunction(gp, p), p)
+
+	gq := new(big.Int).Mod(sk.G, qq)
+	gq.Exp(gq, lq, qq)
+	hq := new(big.Int).ModInverse(lFunction(gq, q), q)
+
+	sk.crt = &crtPrecomputed{
+		pp:       pp,
+		qq:       qq,
+		lp:       lp,
+		lq:       lq,
+		hp:       hp,
+		hq:       hq,
+		qInvModP: new(big.Int).ModInverse(q, p),
+	}
+	return nil
+}
+
+// GenerateKeyPair generates a Paillier key pair whose modulus N is the
+// product of two random primes, each of bits/2 size.
+func GenerateKeyPair(bits int) (*PublicKey, *PrivateKey, error) {
+	p, err := rand.Prime(rand.Reader, bits/2)
+	if err != nil {
+		return nil, nil, err
+	}
+	q, err := rand.Prime(rand.Reader, bits/2)
+	if err != nil {
+		return nil, nil, err
+	}
+	for p.Cmp(q) == 0 {
+		q, err = rand.Prime(rand.Reader, bits/2)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	return keyPairFromPrimes(p, q)
+}
+
+// keyPairFromPrimes builds the full key pair from two distinct primes,
+// shared by GenerateKeyPair and GenerateKeyPairFromSeed.
+func keyPairFromPrimes(p, q *big.Int) (*PublicKey, *PrivateKey, error) {
+	n := new(big.Int).Mul(p, q)
+	n2 := new(big.Int).Mul(n, n)
+	g := new(big.Int).Add(n, one)
+
+	pMinus1 := new(big.Int).Sub(p, one)
+	qMinus1 := new(big.Int).Sub(q, one)
+	lambda := lcm(pMinus1, qMinus1)
+
+	mu := new(big.Int).ModInverse(lambda, n)
+	if mu == nil {
+		return nil, nil, errors.New("paillier: lambda is not invertible mod n, retry key generation")
+	}
+
+	pk := &PublicKey{N: n, G: g, N2: n2}
+	sk := &PrivateKey{
+		PublicKey: *pk,
+		Lambda:    lambda,
+		Mu:        mu,
+		p:         p,
+		q:         q,
+	}
+	return pk, sk, nil
+}
+
+func lcm(a, b *big.Int) *big.Int {
+	gcd := new(big.Int).GCD(nil, nil, a, b)
+	l := new(big.Int).Div(a, gcd)
+	return l.Mul(l, b)
+}
+
+// lFunction computes L(x) = (x-1)/n, the standard Paillier decryption helper.
+func lFunction(x, n *big.Int) *big.Int {
+	return new(big.Int).Div(new(big.Int).Sub(x, one), n)
+}
+
+func validCiphertext(ct, n2 *big.Int) bool {
+	return ct != nil && ct.Sign() > 0 && ct.Cmp(n2) < 0
+}
+
+// Encrypt encrypts m under pk, returning a ciphertext in Z_N2*.
+func (pk *PublicKey) Encrypt(m int64) (*big.Int, error) {
+	ct, _, err := pk.encrypt(big.NewInt(m), rand.Reader)
+	return ct, err
+}
+
+// EncryptWithR behaves like Encrypt but also returns the randomness r used
+// to build the ciphertext (c = G^m * r^N mod N^2), so that callers building
+// a zero-knowledge proof of plaintext knowledge can reuse it instead of
+// having to re-derive it.
+func (pk *PublicKey) EncryptWithR(m int64) (ct *big.Int, r *big.Int, err error) {
+	return pk.encrypt(big.NewInt(m), rand.Reader)
+}
+
+// encrypt is the common implementation behind Encrypt, EncryptWithR and
+// EncryptSigned. It takes the plaintext as a *big.Int so callers that need
+// the full range of Z_N (e.g. a value already reduced via
+// encodeSignedToN) aren't limited to what fits in an int64, and the source
+// of randomness as an io.Reader. All of its callers pass crypto/rand.Reader;
+// EncryptDeterministic derives its randomness itself and goes through
+// EncryptWithRandomness instead.
+func (pk *PublicKey) encrypt(m *big.Int, random io.Reader) (*big.Int, *big.Int, error) {
+	r, err := rand.Int(random, pk.N)
+	if err != nil {
+		return nil, nil, err
+	}
+	if r.Sign() == 0 {
+		r = one
+	}
+
+	gm := new(big.Int).Exp(pk.G, m, pk.N2)
+	rn := new(big.Int).Exp(r, pk.N, pk.N2)
+
+	ct := new(big.Int).Mul(gm, rn)
+	ct.Mod(ct, pk.N2)
+	return ct, r, nil
+}
+
+// Add homomorphically adds two ciphertexts, returning an encryption of the
+// sum of their plaintexts modulo N.
+func (pk *PublicKey) Add(ct1, ct2 *big.Int) (*big.Int, error) {
+	if !validCiphertext(ct1, pk.N2) || !validCiphertext(ct2, pk.N2) {
+		return nil, errors.New("paillier: invalid ciphertext")
+	}
+	sum := new(big.Int).Mul(ct1, ct2)
+	sum.Mod(sum, pk.N2)
+	return sum, nil
+}
+
+// BatchAdd homomorphically adds any number of ciphertexts at once.
+func (pk *PublicKey) BatchAdd(cts ...*big.Int) *big.Int {
+	sum := new(big.Int).Set(one)
+	for _, ct := range cts {
+		sum.Mul(sum, ct)
+		sum.Mod(sum, pk.N2)
+	}
+	return sum
+}
+
+// Sub homomorphically subtracts ct2 from ct1, returning an encryption of
+// their difference modulo N. The underlying plaintext is the usual Paillier
+// wraparound value m mod N; it is Decrypt, not Sub, that recovers the true
+// signed difference for small results (see Decrypt and ratReconstruct).
+func (pk *PublicKey) Sub(ct1, ct2 *big.Int) *big.Int {
+	ct2Inv := new(big.Int).ModInverse(ct2, pk.N2)
+	diff := new(big.Int).Mul(ct1, ct2Inv)
+	diff.Mod(diff, pk.N2)
+	return diff
+}
+
+// AddPlaintext homomorphically adds a known plaintext constant to ct.
+func (pk *PublicKey) AddPlaintext(ct *big.Int, pt int64) (*big.Int, error) {
+	if !validCiphertext(ct, pk.N2) {
+		return nil, errors.New("paillier: invalid ciphertext")
+	}
+	gpt := new(big.Int).Exp(pk.G, big.NewInt(pt), pk.N2)
+	sum := new(big.Int).Mul(ct, gpt)
+	sum.Mod(sum, pk.N2)
+	return sum, nil
+}
+
+// MultPlaintext homomorphically multiplies ct by a known plaintext constant.
+func (pk *PublicKey) MultPlaintext(ct *big.Int, pt int64) (*big.Int, error) {
+	if !validCiphertext(ct, pk.N2) {
+		return nil, errors.New("paillier: invalid ciphertext")
+	}
+	product := new(big.Int).Exp(ct, big.NewInt(pt), pk.N2)
+	return product, nil
+}
+
+// DivPlaintext homomorphically divides ct by a known plaintext constant.
+// It multiplies the encrypted exponent by the modular inverse of pt, which
+// yields an exact result whenever pt evenly divides the underlying
+// plaintext; Decrypt recovers the (possibly rounded) quotient via rational
+// reconstruction, see ratReconstruct.
+func (pk *PublicKey) DivPlaintext(ct *big.Int, pt int64) (*big.Int, error) {
+	if !validCiphertext(ct, pk.N2) {
+		return nil, errors.New("paillier: invalid ciphertext")
+	}
+	ptInv := new(big.Int).ModInverse(big.NewInt(pt), pk.N)
+	if ptInv == nil {
+		return nil, errors.New("paillier: divisor is not invertible mod n")
+	}
+	quotient := new(big.Int).Exp(ct, ptInv, pk.N2)
+	return quotient, nil
+}
+
+// Decrypt recovers the plaintext integer encrypted in ct. Unlike plain
+// modular decryption, it does not return the raw m mod N: it runs the
+// result through ratReconstruct, which recognizes the case where m mod N
+// is really a small rational num/den reduced modulo N (as produced by, for
+// instance, an inexact DivPlaintext, or a Sub whose true result is
+// negative) and recovers num/den instead. So, e.g., Decrypt(Sub(Encrypt(2),
+// Encrypt(5))) returns -3, not a huge value near N.
+func (sk *PrivateKey) Decrypt(ct *big.Int) (int64, error) {
+	if !validCiphertext(ct, sk.N2) {
+		return 0, errors.New("paillier: invalid ciphertext")
+	}
+
+	raw := sk.decrypt(ct)
+
+	num, den, ok := ratReconstruct(raw, sk.N)
+	if !ok {
+		return raw.Int64(), nil
+	}
+	return new(big.Int).Quo(num, den).Int64(), nil
+}
+
+// decrypt performs the core Paillier decryption, returning m mod N. When
+// Precompute has been called, it recombines per-prime partial decryptions
+// via CRT instead of exponentiating modulo the much larger N^2.
+func (sk *PrivateKey) decrypt(ct *big.Int) *big.Int {
+	sk.crtMu.RLock()
+	crt := sk.crt
+	sk.crtMu.RUnlock()
+	if crt != nil {
+		return sk.decryptCRT(ct, crt)
+	}
+
+	u := new(big.Int).Exp(ct, sk.Lambda, sk.N2)
+	m := lFunction(u, sk.N)
+	m.Mul(m, sk.Mu)
+	m.Mod(m, sk.N)
+	return m
+}
+
+// decryptCRT computes m_p and m_q modulo the (much smaller) primes p and q
+// and recombines them via CRT: m = m_q + q*((q^-1 mod p)*(m_p - m_q) mod p).
+func (sk *PrivateKey) decryptCRT(ct *big.Int, crt *crtPrecomputed) *big.Int {
+	p, q := sk.p, sk.q
+
+	cp := new(big.Int).Mod(ct, crt.pp)
+	cp.Exp(cp, crt.lp, crt.pp)
+	mp := lFunction(cp, p)
+	mp.Mul(mp, crt.hp)
+	mp.Mod(mp, p)
+
+	cq := new(big.Int).Mod(ct, crt.qq)
+	cq.Exp(cq, crt.lq, crt.qq)
+	mq := lFunction(cq, q)
+	mq.Mul(mq, crt.hq)
+	mq.Mod(mq, q)
+
+	h := new(big.Int).Sub(mp, mq)
+	h.Mul(h, crt.qInvModP)
+	h.Mod(h, p)
+
+	m := new(big.Int).Mul(q, h)
+	m.Add(m, mq)
+	return m
+}
+
+// ratReconstruct recovers the smallest-magnitude fraction num/den (in lowest
+// terms) such that num ≡ den*x (mod n), using the extended Euclidean
+// algorithm over the continued fraction expansion of n/x. This is a no-op
+// for plaintexts that are already small (it returns them as num/1), and is
+// what lets DivPlaintext's modular-inverse trick recover the true quotient
+// even when the division is inexact.
+func ratReconstruct(x, n *big.Int) (num, den *big.Int, ok bool) {
+	if x.Sign() == 0 {
+		return zero, one, true
+	}
+
+	bound := new(big.Int).Rsh(n, 1)
+	bound.Sqrt(bound)
+
+	r0, r1 := new(big.Int).Set(n), new(big.Int).Set(x)
+	t0, t1 := big.NewInt(0), big.NewInt(1)
+
+	for r1.CmpAbs(bound) > 0 {
+		if r1.Sign() == 0 {
+			return nil, nil, false
+		}
+		q, rem := new(big.Int), new(big.Int)
+		q.DivMod(r0, r1, rem)
+		r0, r1 = r1, rem
+		t0, t1 = t1, new(big.Int).Sub(t0, new(big.Int).Mul(q, t1))
+	}
+	if t1.Sign() == 0 {
+		return nil, nil, false
+	}
+
+	num, den = r1, t1
+	if den.Sign() < 0 {
+		num.Neg(num)
+		den.Neg(den)
+	}
+	g := new(big.Int).GCD(nil, nil, new(big.Int).Abs(num), den)
+	if g.Sign() != 0 {
+		num.Div(num, g)
+		den.Div(den, g)
+	}
+	return num, den, true
+}
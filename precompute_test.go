@@ -0,0 +1,82 @@
+package paillier
+
+import (
+	"math/big"
+	"testing"
+)
+
+// TestPrivateKey_Precompute checks that enabling the CRT decryption path
+// via Precompute produces the same results as the non-precomputed path,
+// for 0, 1 and several of the plaintexts encrypted in homomorphism_test.go.
+func TestPrivateKey_Precompute(t *testing.T) {
+	pk, sk, err := GenerateKeyPair(1024)
+	if err != nil {
+		t.Fatalf("GenerateKeyPair() error = %v", err)
+	}
+
+	plaintexts := []int64{0, 1, 2, 36, 245, 23578}
+	cts := make([]*big.Int, len(plaintexts))
+	for i, m := range plaintexts {
+		ct, err := pk.Encrypt(m)
+		if err != nil {
+			t.Fatalf("Encrypt(%d) error = %v", m, err)
+		}
+		cts[i] = ct
+	}
+
+	want := make([]int64, len(plaintexts))
+	for i, ct := range cts {
+		m, err := sk.Decrypt(ct)
+		if err != nil {
+			t.Fatalf("Decrypt() error = %v", err)
+		}
+		want[i] = m
+	}
+
+	if err := sk.Precompute(); err != nil {
+		t.Fatalf("Precompute() error = %v", err)
+	}
+	for i, ct := range cts {
+		got, err := sk.Decrypt(ct)
+		if err != nil {
+			t.Fatalf("Decrypt() with precomputed CRT values error = %v", err)
+		}
+		if got != want[i] {
+			t.Errorf("Decrypt() with precomputed CRT values = %v, want %v", got, want[i])
+		}
+	}
+}
+
+// TestPrivateKey_PrecomputeConcurrent ensures Precompute can safely be
+// called lazily from multiple goroutines, as intended for high-throughput
+// decryption paths.
+func TestPrivateKey_PrecomputeConcurrent(t *testing.T) {
+	pk, sk, err := GenerateKeyPair(1024)
+	if err != nil {
+		t.Fatalf("GenerateKeyPair() error = %v", err)
+	}
+
+	ct, err := pk.Encrypt(42)
+	if err != nil {
+		t.Fatalf("Encrypt() error = %v", err)
+	}
+
+	done := make(chan int64, 8)
+	for i := 0; i < 8; i++ {
+		go func() {
+			if err := sk.Precompute(); err != nil {
+				t.Errorf("Precompute() error = %v", err)
+			}
+			m, err := sk.Decrypt(ct)
+			if err != nil {
+				t.Errorf("Decrypt() error = %v", err)
+			}
+			done <- m
+		}()
+	}
+	for i := 0; i < 8; i++ {
+		if m := <-done; m != 42 {
+			t.Errorf("Decrypt() = %v, want 42", m)
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package paillier
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math/big"
+)
+
+// hkdfExtract implements the "extract" half of HKDF (RFC 5869): it
+// concentrates the (possibly low-entropy-looking) seed into a
+// fixed-length, uniformly-random pseudorandom key.
+func hkdfExtract(salt, ikm []byte) []byte {
+	mac := hmac.New(sha256.New, salt)
+	mac.Write(ikm)
+	return mac.Sum(nil)
+}
+
+// seedSalt domain-separates GenerateKeyPairFromSeed and friends from any
+// other HMAC-SHA256 use of the caller's seed bytes.
+var seedSalt = []byte("paillier/v1/hkdf-seed")
+
+// hkdfStream is a deterministic, effectively infinite byte stream derived
+// from a seed via HKDF-Extract followed by an HMAC-SHA256 counter-mode
+// expansion, keyed on the extracted key, over info. It implements io.Reader so it can be passed
+// anywhere crypto/rand.Reader normally would, in particular to
+// crypto/rand.Prime and crypto/rand.Int, making prime search (and hence key
+// generation) and ciphertext randomness fully reproducible from a seed.
+//
+// This intentionally departs from RFC 5869's HKDF-Expand, which caps output
+// at 255 hash blocks (8160 bytes for SHA-256): crypto/rand.Prime can reject
+// many candidates before finding a prime and may need far more than that,
+// so blocks are indexed by an unbounded uint64 counter instead of a single
+// byte.
+type hkdfStream struct {
+	prk     []byte
+	info    []byte
+	counter uint64
+	buf     []byte
+}
+
+func newHKDFStream(seed []byte, info string) *hkdfStream {
+	return &hkdfStream{prk: hkdfExtract(seedSalt, seed), info: []byte(info)}
+}
+
+func (s *hkdfStream) Read(p []byte) (int, error) {
+	n := 0
+	for n < len(p) {
+		if len(s.buf) == 0 {
+			s.counter++
+			mac := hmac.New(sha256.New, s.prk)
+			mac.Write(s.info)
+			var ctr [8]byte
+			binary.BigEndian.PutUint64(ctr[:], s.counter)
+			mac.Write(ctr[:])
+			s.buf = mac.Sum(nil)
+		}
+		copied := copy(p[n:], s.buf)
+		s.buf = s.buf[copied:]
+		n += copied
+	}
+	return n, nil
+}
+
+// GenerateKeyPairFromSeed deterministically derives a Paillier key pair
+// from seed: the same seed always yields the same (p, q) and hence the
+// same keys, by driving the prime search with an HKDF-SHA256 stream (see
+// hkdfStream) instead of crypto/rand.Reader. This is useful for
+// reproducible test vectors, for backing up a key as a mnemonic-style seed
+// phrase, and for MPC protocols where multiple parties must independently
+// re-derive the same key material.
+func GenerateKeyPairFromSeed(bits int, seed []byte) (*PublicKey, *PrivateKey, error) {
+	p, err := deterministicPrime(newHKDFStream(seed, "paillier-prime-p"), bits/2)
+	if err != nil {
+		return nil, nil, err
+	}
+	q, err := deterministicPrime(newHKDFStream(seed, "paillier-prime-q"), bits/2)
+	if err != nil {
+		return nil, nil, err
+	}
+	if p.Cmp(q) == 0 {
+		return nil, nil, errors.New("paillier: seed produced equal p and q, choose a different seed")
+	}
+	return keyPairFromPrimes(p, q)
+}
+
+// deterministicPrime finds a prime of the given bit length using only the
+// bytes produced by random, following the same candidate construction as
+// crypto/rand.Prime. It cannot simply call crypto/rand.Prime: that function
+// deliberately consumes an extra, non-deterministically-timed byte from its
+// reader (crypto/internal/randutil.MaybeReadByte) specifically so that
+// callers can't rely on it being a pure function of the random stream, which
+// is exactly the property GenerateKeyPairFromSeed needs.
+func deterministicPrime(random io.Reader, bits int) (*big.Int, error) {
+	if bits < 2 {
+		return nil, errors.New("paillier: prime size must be at least 2 bits")
+	}
+
+	b := uint(bits % 8)
+	if b == 0 {
+		b = 8
+	}
+
+	bytes := make([]byte, (bits+7)/8)
+	p := new(big.Int)
+
+	for {
+		if _, err := io.ReadFull(random, bytes); err != nil {
+			return nil, err
+		}
+
+		bytes[0] &= uint8(1<<b - 1)
+		if b >= 2 {
+			bytes[0] |= 3 << (b - 2)
+		} else {
+			bytes[0] |= 1
+			if len(bytes) > 1 {
+				bytes[1] |= 0x80
+			}
+		}
+		bytes[len(bytes)-1] |= 1
+
+		p.SetBytes(bytes)
+		if p.ProbablyPrime(20) {
+			return p, nil
+		}
+	}
+}
+
+// EncryptWithRandomness encrypts m using the caller-supplied randomness r
+// (which must satisfy 0 < r < N), producing exactly c = G^m * r^N mod N^2.
+// It is the building block EncryptDeterministic uses, and is also useful on
+// its own for verifying a ciphertext against a claimed r.
+func (pk *PublicKey) EncryptWithRandomness(m int64, r *big.Int) (*big.Int, error) {
+	if r == nil || r.Sign() <= 0 || r.Cmp(pk.N) >= 0 {
+		return nil, errors.New("paillier: randomness out of range [1, n)")
+	}
+	gm := new(big.Int).Exp(pk.G, big.NewInt(m), pk.N2)
+	rn := new(big.Int).Exp(r, pk.N, pk.N2)
+	ct := new(big.Int).Mul(gm, rn)
+	ct.Mod(ct, pk.N2)
+	return ct, nil
+}
+
+// EncryptDeterministic encrypts m using randomness derived from nonce via
+// an HKDF-SHA256 stream, so the same (pk, m, nonce) always produces the
+// same ciphertext. Callers are responsible for never reusing a nonce under
+// the same key for different plaintexts, exactly as with any other
+// nonce-based deterministic encryption scheme.
+func (pk *PublicKey) EncryptDeterministic(m int64, nonce []byte) (*big.Int, error) {
+	r, err := rand.Int(newHKDFStream(nonce, "paillier-r"), pk.N)
+	if err != nil {
+		return nil, err
+	}
+	if r.Sign() == 0 {
+		r = one
+	}
+	return pk.EncryptWithRandomness(m, r)
+}
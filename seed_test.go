@@ -0,0 +1,152 @@
+package paillier
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+)
+
+func TestGenerateKeyPairFromSeed_Deterministic(t *testing.T) {
+	seed := []byte("test seed for reproducible key generation")
+
+	pk1, sk1, err := GenerateKeyPairFromSeed(512, seed)
+	if err != nil {
+		t.Fatalf("GenerateKeyPairFromSeed() error = %v", err)
+	}
+	pk2, sk2, err := GenerateKeyPairFromSeed(512, seed)
+	if err != nil {
+		t.Fatalf("GenerateKeyPairFromSeed() error = %v", err)
+	}
+
+	if pk1.N.Cmp(pk2.N) != 0 {
+		t.Errorf("GenerateKeyPairFromSeed() not deterministic: N1 = %v, N2 = %v", pk1.N, pk2.N)
+	}
+	if sk1.Lambda.Cmp(sk2.Lambda) != 0 || sk1.Mu.Cmp(sk2.Mu) != 0 {
+		t.Errorf("GenerateKeyPairFromSeed() not deterministic: private key material differs")
+	}
+
+	ct, err := pk1.Encrypt(123)
+	if err != nil {
+		t.Fatalf("Encrypt() error = %v", err)
+	}
+	m, err := sk2.Decrypt(ct)
+	if err != nil {
+		t.Fatalf("Decrypt() error = %v", err)
+	}
+	if m != 123 {
+		t.Errorf("Decrypt() with key derived from the same seed = %v, want 123", m)
+	}
+}
+
+func TestGenerateKeyPairFromSeed_DifferentSeedsDiffer(t *testing.T) {
+	pk1, _, err := GenerateKeyPairFromSeed(512, []byte("seed A"))
+	if err != nil {
+		t.Fatalf("GenerateKeyPairFromSeed() error = %v", err)
+	}
+	pk2, _, err := GenerateKeyPairFromSeed(512, []byte("seed B"))
+	if err != nil {
+		t.Fatalf("GenerateKeyPairFromSeed() error = %v", err)
+	}
+	if pk1.N.Cmp(pk2.N) == 0 {
+		t.Errorf("GenerateKeyPairFromSeed() produced the same N for different seeds")
+	}
+}
+
+func TestPublicKey_EncryptWithRandomness(t *testing.T) {
+	pk, sk, err := GenerateKeyPair(1024)
+	if err != nil {
+		t.Fatalf("GenerateKeyPair() error = %v", err)
+	}
+
+	r := big.NewInt(12345)
+	ct, err := pk.EncryptWithRandomness(7, r)
+	if err != nil {
+		t.Fatalf("EncryptWithRandomness() error = %v", err)
+	}
+
+	want := new(big.Int).Exp(pk.G, big.NewInt(7), pk.N2)
+	want.Mul(want, new(big.Int).Exp(r, pk.N, pk.N2))
+	want.Mod(want, pk.N2)
+	if ct.Cmp(want) != 0 {
+		t.Errorf("EncryptWithRandomness() = %v, want %v", ct, want)
+	}
+
+	m, err := sk.Decrypt(ct)
+	if err != nil {
+		t.Fatalf("Decrypt() error = %v", err)
+	}
+	if m != 7 {
+		t.Errorf("Decrypt(EncryptWithRandomness(7, r)) = %v, want 7", m)
+	}
+
+	if _, err := pk.EncryptWithRandomness(7, pk.N); err == nil {
+		t.Error("EncryptWithRandomness() with r == N did not return an error")
+	}
+}
+
+func TestPublicKey_EncryptDeterministic(t *testing.T) {
+	pk, sk, err := GenerateKeyPair(1024)
+	if err != nil {
+		t.Fatalf("GenerateKeyPair() error = %v", err)
+	}
+
+	nonce := []byte("unique per-message nonce")
+	ct1, err := pk.EncryptDeterministic(99, nonce)
+	if err != nil {
+		t.Fatalf("EncryptDeterministic() error = %v", err)
+	}
+	ct2, err := pk.EncryptDeterministic(99, nonce)
+	if err != nil {
+		t.Fatalf("EncryptDeterministic() error = %v", err)
+	}
+	if ct1.Cmp(ct2) != 0 {
+		t.Errorf("EncryptDeterministic() not deterministic for the same nonce")
+	}
+
+	ct3, err := pk.EncryptDeterministic(99, []byte("a different nonce"))
+	if err != nil {
+		t.Fatalf("EncryptDeterministic() error = %v", err)
+	}
+	if ct1.Cmp(ct3) == 0 {
+		t.Errorf("EncryptDeterministic() produced the same ciphertext for different nonces")
+	}
+
+	m, err := sk.Decrypt(ct1)
+	if err != nil {
+		t.Fatalf("Decrypt() error = %v", err)
+	}
+	if m != 99 {
+		t.Errorf("Decrypt(EncryptDeterministic(99, nonce)) = %v, want 99", m)
+	}
+}
+
+func TestPublicKey_EncryptWithR(t *testing.T) {
+	pk, sk, err := GenerateKeyPair(1024)
+	if err != nil {
+		t.Fatalf("GenerateKeyPair() error = %v", err)
+	}
+
+	ct, r, err := pk.EncryptWithR(55)
+	if err != nil {
+		t.Fatalf("EncryptWithR() error = %v", err)
+	}
+	if r == nil || r.Sign() <= 0 || r.Cmp(pk.N) >= 0 {
+		t.Fatalf("EncryptWithR() returned r out of range: %v", r)
+	}
+
+	recomputed, err := pk.EncryptWithRandomness(55, r)
+	if err != nil {
+		t.Fatalf("EncryptWithRandomness() error = %v", err)
+	}
+	if !bytes.Equal(ct.Bytes(), recomputed.Bytes()) {
+		t.Errorf("EncryptWithR()'s r does not reproduce its own ciphertext")
+	}
+
+	m, err := sk.Decrypt(ct)
+	if err != nil {
+		t.Fatalf("Decrypt() error = %v", err)
+	}
+	if m != 55 {
+		t.Errorf("Decrypt(EncryptWithR(55)) = %v, want 55", m)
+	}
+}
@@ -0,0 +1,353 @@
+// Package threshold implements Damgård–Jurik–Nielsen threshold Paillier
+// decryption: a trusted dealer splits a Paillier private key into l shares
+// such that any t+1 of them can jointly decrypt a ciphertext without any
+// party, or the combiner, ever learning the shared private key.
+//
+// Ciphertexts are ordinary *paillier.PublicKey ciphertexts, so callers can
+// build up a homomorphic tally with the parent package's Add, Sub,
+// MultPlaintext and BatchAdd before handing the result to this package for
+// threshold decryption.
+package threshold
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"math/big"
+
+	"github.com/Cryptographer63/paillier"
+)
+
+var one = big.NewInt(1)
+
+// statisticalHidingBits is added to the bit length of the values shared via
+// Shamir secret sharing (and of the randomness used in the zero-knowledge
+// proofs below) so that a share, or a proof transcript, statistically hides
+// the secret it's derived from even though the sharing is done over the
+// integers rather than modulo a known group order.
+const statisticalHidingBits = 128
+
+// challengeBits bounds the Fiat-Shamir challenge e used in
+// proveEqualityOfExponent: fiatShamirChallenge truncates its SHA-256 output
+// to this many bits so that the masking randomness r only has to swamp a
+// challenge of known, fixed size rather than an up-to-256-bit hash.
+const challengeBits = 128
+
+// PublicKey is a Paillier public key together with the public material
+// needed to verify partial decryptions produced by a KeyShare.
+type PublicKey struct {
+	*paillier.PublicKey
+	L, T             int      // L shares exist; any T+1 of them can decrypt.
+	Theta            *big.Int // the public constant beta*lambda mod n, fixed at key generation.
+	V                *big.Int // public base for the Chaum-Pedersen proofs below.
+	VerificationKeys []*big.Int // VerificationKeys[i] = V^(share i's secret) mod N2, 1-indexed; index 0 is unused.
+}
+
+// KeyShare is one party's share of a threshold-decryption key. It carries
+// no information about the master private key on its own.
+type KeyShare struct {
+	Index int      // this party's index, 1..Pub.L
+	Share *big.Int // s_i = f(i), this party's Shamir share of beta*lambda
+	Pub   *PublicKey
+}
+
+// Proof is a non-interactive Chaum-Pedersen proof of equality of discrete
+// logs: it shows that the exponent used to produce a DecryptionShare is the
+// same exponent used to produce the share's public verification key,
+// without revealing that exponent.
+type Proof struct {
+	A, B, Z *big.Int
+}
+
+// DecryptionShare is one party's partial decryption of a ciphertext, along
+// with a Proof that it was computed correctly.
+type DecryptionShare struct {
+	Index int
+	Value *big.Int
+	Proof *Proof
+}
+
+// GenerateThresholdKeyPair acts as the trusted dealer: it generates a
+// Paillier key pair of the given bit size and splits its private exponent
+// into l shares via Shamir secret sharing over the integers, such that any
+// t+1 shares suffice to decrypt. No party, including the caller, retains
+// the reconstructed private key afterwards.
+func GenerateThresholdKeyPair(bits, l, t int) (*PublicKey, []*KeyShare, error) {
+	if t < 0 || l <= t {
+		return nil, nil, errors.New("threshold: need 0 <= t < l")
+	}
+
+	pk, sk, err := paillier.GenerateKeyPair(bits)
+	if err != nil {
+		return nil, nil, err
+	}
+	lambda := sk.Lambda
+
+	beta, err := randCoprimeTo(pk.N)
+	if err != nil {
+		return nil, nil, err
+	}
+	d := new(big.Int).Mul(beta, lambda)
+	theta := new(big.Int).Mod(d, pk.N)
+
+	coeffs, err := shamirCoefficients(d, t)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	v, err := randSquareMod(pk.N2)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tpk := &PublicKey{
+		PublicKey:        pk,
+		L:                l,
+		T:                t,
+		Theta:            theta,
+		V:                v,
+		VerificationKeys: make([]*big.Int, l+1),
+	}
+
+	shares := make([]*KeyShare, l)
+	for i := 1; i <= l; i++ {
+		s := evalPolynomial(coeffs, int64(i))
+		tpk.VerificationKeys[i] = new(big.Int).Exp(v, s, pk.N2)
+		shares[i-1] = &KeyShare{Index: i, Share: s, Pub: tpk}
+	}
+	return tpk, shares, nil
+}
+
+// delta returns l!, the constant Damgård-Jurik-Nielsen use to clear
+// denominators in the Lagrange interpolation performed by Combine.
+func delta(l int) *big.Int {
+	d := big.NewInt(1)
+	for i := 2; i <= l; i++ {
+		d.Mul(d, big.NewInt(int64(i)))
+	}
+	return d
+}
+
+// PartialDecrypt computes this party's contribution towards decrypting ct,
+// together with a proof that the contribution was derived from the same
+// secret share used to build the party's public verification key.
+func (share *KeyShare) PartialDecrypt(ct *big.Int) (*DecryptionShare, error) {
+	n2 := share.Pub.N2
+	base := new(big.Int).Exp(ct, new(big.Int).Lsh(delta(share.Pub.L), 1), n2) // c^(2*Delta)
+
+	value := new(big.Int).Exp(base, share.Share, n2) // c^(2*Delta*s_i)
+
+	proof, err := proveEqualityOfExponent(base, share.Pub.V, share.Share, n2, value, share.Pub.VerificationKeys[share.Index])
+	if err != nil {
+		return nil, err
+	}
+	return &DecryptionShare{Index: share.Index, Value: value, Proof: proof}, nil
+}
+
+// VerifyDecryptionShare checks that ds was honestly computed for ct under
+// pk's key share with the given index, without needing the share itself.
+func VerifyDecryptionShare(pk *PublicKey, ct *big.Int, ds *DecryptionShare) bool {
+	if ds.Index < 1 || ds.Index > pk.L {
+		return false
+	}
+	base := new(big.Int).Exp(ct, new(big.Int).Lsh(delta(pk.L), 1), pk.N2)
+	return verifyEqualityOfExponent(base, pk.V, pk.N2, ds.Value, pk.VerificationKeys[ds.Index], ds.Proof)
+}
+
+// Combine recombines t+1 (or more) decryption shares of the same ciphertext
+// into the plaintext. Only the first t+1 shares are used; each of them has
+// its proof verified first, and Combine returns an error if any of them is
+// invalid or repeats an index. Shares beyond the first t+1 are not checked.
+func Combine(pk *PublicKey, ct *big.Int, shares []*DecryptionShare) (int64, error) {
+	if len(shares) < pk.T+1 {
+		return 0, errors.New("threshold: not enough decryption shares")
+	}
+	shares = shares[:pk.T+1]
+
+	indices := make([]int, len(shares))
+	seen := make(map[int]bool, len(shares))
+	for i, s := range shares {
+		if !VerifyDecryptionShare(pk, ct, s) {
+			return 0, errors.New("threshold: invalid decryption share")
+		}
+		if seen[s.Index] {
+			return 0, errors.New("threshold: duplicate decryption share index")
+		}
+		seen[s.Index] = true
+		indices[i] = s.Index
+	}
+
+	Delta := delta(pk.L)
+	cPrime := big.NewInt(1)
+	for i, s := range shares {
+		lambda0i, err := lagrangeCoefficientAtZero(indices, i, Delta)
+		if err != nil {
+			return 0, err
+		}
+		exp := new(big.Int).Lsh(lambda0i, 1) // 2*lambda_{0,i}
+
+		var term *big.Int
+		if exp.Sign() >= 0 {
+			term = new(big.Int).Exp(s.Value, exp, pk.N2)
+		} else {
+			inv := new(big.Int).ModInverse(s.Value, pk.N2)
+			if inv == nil {
+				return 0, errors.New("threshold: decryption share is not invertible mod n^2")
+			}
+			term = new(big.Int).Exp(inv, new(big.Int).Neg(exp), pk.N2)
+		}
+		cPrime.Mul(cPrime, term)
+		cPrime.Mod(cPrime, pk.N2)
+	}
+
+	raw := lFunction(cPrime, pk.N)
+
+	denom := new(big.Int).Lsh(Delta, 2) // 4*Delta
+	denom.Mul(denom, Delta)             // 4*Delta^2
+	denom.Mul(denom, pk.Theta)
+	denom.Mod(denom, pk.N)
+	denomInv := new(big.Int).ModInverse(denom, pk.N)
+	if denomInv == nil {
+		return 0, errors.New("threshold: combination constant is not invertible mod n")
+	}
+
+	m := new(big.Int).Mul(raw, denomInv)
+	m.Mod(m, pk.N)
+	return m.Int64(), nil
+}
+
+// lFunction computes (x-1)/n, the standard Paillier decryption helper.
+func lFunction(x, n *big.Int) *big.Int {
+	return new(big.Int).Div(new(big.Int).Sub(x, one), n)
+}
+
+// lagrangeCoefficientAtZero computes Delta * prod_{j != indices[which]} -j/(i-j),
+// the Lagrange coefficient for evaluating the sharing polynomial at 0 from
+// the given set of indices. Delta = l! guarantees this is an exact integer.
+func lagrangeCoefficientAtZero(indices []int, which int, Delta *big.Int) (*big.Int, error) {
+	i := indices[which]
+	num := new(big.Int).Set(Delta)
+	den := big.NewInt(1)
+	for k, j := range indices {
+		if k == which {
+			continue
+		}
+		num.Mul(num, big.NewInt(int64(-j)))
+		den.Mul(den, big.NewInt(int64(i-j)))
+	}
+	coeff, rem := new(big.Int), new(big.Int)
+	coeff.QuoRem(num, den, rem)
+	if rem.Sign() != 0 {
+		return nil, errors.New("threshold: lagrange coefficient is not an integer")
+	}
+	return coeff, nil
+}
+
+// shamirCoefficients builds the coefficients of a degree-t polynomial whose
+// constant term is the secret d, with the remaining coefficients drawn
+// uniformly from a range large enough to statistically hide d.
+func shamirCoefficients(d *big.Int, t int) ([]*big.Int, error) {
+	bound := new(big.Int).Lsh(one, uint(d.BitLen()+statisticalHidingBits))
+	coeffs := make([]*big.Int, t+1)
+	coeffs[0] = d
+	for k := 1; k <= t; k++ {
+		c, err := rand.Int(rand.Reader, bound)
+		if err != nil {
+			return nil, err
+		}
+		coeffs[k] = c
+	}
+	return coeffs, nil
+}
+
+// evalPolynomial evaluates the polynomial with the given coefficients
+// (lowest degree first) at x, using Horner's method.
+func evalPolynomial(coeffs []*big.Int, x int64) *big.Int {
+	xb := big.NewInt(x)
+	res := new(big.Int).Set(coeffs[len(coeffs)-1])
+	for k := len(coeffs) - 2; k >= 0; k-- {
+		res.Mul(res, xb)
+		res.Add(res, coeffs[k])
+	}
+	return res
+}
+
+func randCoprimeTo(n *big.Int) (*big.Int, error) {
+	for {
+		b, err := rand.Int(rand.Reader, n)
+		if err != nil {
+			return nil, err
+		}
+		if b.Sign() == 0 {
+			continue
+		}
+		if new(big.Int).GCD(nil, nil, b, n).Cmp(one) == 0 {
+			return b, nil
+		}
+	}
+}
+
+func randSquareMod(n2 *big.Int) (*big.Int, error) {
+	r, err := rand.Int(rand.Reader, n2)
+	if err != nil {
+		return nil, err
+	}
+	if r.Sign() == 0 {
+		r = one
+	}
+	return r.Mul(r, r).Mod(r, n2), nil
+}
+
+// proveEqualityOfExponent proves, without revealing x, that value = base^x
+// mod n2 and pub = gen^x mod n2 for the same x (a non-interactive
+// Chaum-Pedersen proof of equality of discrete logs, using Fiat-Shamir).
+//
+// z = e*x + r must statistically hide x, so r is drawn from a range that
+// swamps e*x across the entire challenge space: its bit length accounts for
+// both x and the (bounded, see challengeBits) challenge e, plus the usual
+// statistical-hiding slack.
+func proveEqualityOfExponent(base, gen, x, n2, value, pub *big.Int) (*Proof, error) {
+	bound := new(big.Int).Lsh(one, uint(x.BitLen()+challengeBits+statisticalHidingBits))
+	r, err := rand.Int(rand.Reader, bound)
+	if err != nil {
+		return nil, err
+	}
+
+	a := new(big.Int).Exp(base, r, n2)
+	b := new(big.Int).Exp(gen, r, n2)
+	e := fiatShamirChallenge(base, gen, value, pub, a, b)
+
+	z := new(big.Int).Mul(e, x)
+	z.Add(z, r)
+	return &Proof{A: a, B: b, Z: z}, nil
+}
+
+func verifyEqualityOfExponent(base, gen, n2, value, pub *big.Int, proof *Proof) bool {
+	e := fiatShamirChallenge(base, gen, value, pub, proof.A, proof.B)
+
+	lhs1 := new(big.Int).Exp(base, proof.Z, n2)
+	rhs1 := new(big.Int).Exp(value, e, n2)
+	rhs1.Mul(rhs1, proof.A)
+	rhs1.Mod(rhs1, n2)
+	if lhs1.Cmp(rhs1) != 0 {
+		return false
+	}
+
+	lhs2 := new(big.Int).Exp(gen, proof.Z, n2)
+	rhs2 := new(big.Int).Exp(pub, e, n2)
+	rhs2.Mul(rhs2, proof.B)
+	rhs2.Mod(rhs2, n2)
+	return lhs2.Cmp(rhs2) == 0
+}
+
+// fiatShamirChallenge hashes values into a challenge e bounded to
+// challengeBits bits, as required by proveEqualityOfExponent's masking
+// argument.
+func fiatShamirChallenge(values ...*big.Int) *big.Int {
+	h := sha256.New()
+	for _, v := range values {
+		h.Write(v.Bytes())
+	}
+	e := new(big.Int).SetBytes(h.Sum(nil))
+	return e.Rsh(e, uint(sha256.Size*8-challengeBits))
+}
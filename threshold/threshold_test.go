@@ -0,0 +1,154 @@
+package threshold
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestThreshold_DecryptRecoversPlaintext(t *testing.T) {
+	pk, shares, err := GenerateThresholdKeyPair(512, 5, 2)
+	if err != nil {
+		t.Fatalf("GenerateThresholdKeyPair() error = %v", err)
+	}
+
+	ct, err := pk.Encrypt(42)
+	if err != nil {
+		t.Fatalf("Encrypt() error = %v", err)
+	}
+
+	// Only t+1 of the l shares are needed; use an arbitrary subset.
+	ds := make([]*DecryptionShare, 0, pk.T+1)
+	for _, idx := range []int{2, 4, 5} {
+		d, err := shares[idx-1].PartialDecrypt(ct)
+		if err != nil {
+			t.Fatalf("PartialDecrypt() error = %v", err)
+		}
+		if !VerifyDecryptionShare(pk, ct, d) {
+			t.Fatalf("VerifyDecryptionShare() rejected an honest share from party %d", idx)
+		}
+		ds = append(ds, d)
+	}
+
+	m, err := Combine(pk, ct, ds)
+	if err != nil {
+		t.Fatalf("Combine() error = %v", err)
+	}
+	if m != 42 {
+		t.Errorf("Combine() = %v, want 42", m)
+	}
+}
+
+func TestThreshold_HomomorphicTallyThenDecrypt(t *testing.T) {
+	pk, shares, err := GenerateThresholdKeyPair(512, 4, 1)
+	if err != nil {
+		t.Fatalf("GenerateThresholdKeyPair() error = %v", err)
+	}
+
+	votes := []int64{1, 1, 0, 1, 0}
+	cts := make([]*big.Int, len(votes))
+	for i, v := range votes {
+		ct, err := pk.Encrypt(v)
+		if err != nil {
+			t.Fatalf("Encrypt() error = %v", err)
+		}
+		cts[i] = ct
+	}
+	tally := pk.BatchAdd(cts...)
+
+	ds := make([]*DecryptionShare, 0, pk.T+1)
+	for _, idx := range []int{1, 3} {
+		d, err := shares[idx-1].PartialDecrypt(tally)
+		if err != nil {
+			t.Fatalf("PartialDecrypt() error = %v", err)
+		}
+		ds = append(ds, d)
+	}
+
+	m, err := Combine(pk, tally, ds)
+	if err != nil {
+		t.Fatalf("Combine() error = %v", err)
+	}
+	var want int64
+	for _, v := range votes {
+		want += v
+	}
+	if m != want {
+		t.Errorf("Combine() tally = %v, want %v", m, want)
+	}
+}
+
+func TestThreshold_RejectsTooFewShares(t *testing.T) {
+	pk, shares, err := GenerateThresholdKeyPair(512, 5, 2)
+	if err != nil {
+		t.Fatalf("GenerateThresholdKeyPair() error = %v", err)
+	}
+	ct, err := pk.Encrypt(7)
+	if err != nil {
+		t.Fatalf("Encrypt() error = %v", err)
+	}
+
+	ds := make([]*DecryptionShare, 0, pk.T)
+	for _, idx := range []int{1, 2} {
+		d, err := shares[idx-1].PartialDecrypt(ct)
+		if err != nil {
+			t.Fatalf("PartialDecrypt() error = %v", err)
+		}
+		ds = append(ds, d)
+	}
+
+	if _, err := Combine(pk, ct, ds); err == nil {
+		t.Error("Combine() with only t shares did not return an error")
+	}
+}
+
+func TestThreshold_RejectsForgedShare(t *testing.T) {
+	pk, shares, err := GenerateThresholdKeyPair(512, 5, 2)
+	if err != nil {
+		t.Fatalf("GenerateThresholdKeyPair() error = %v", err)
+	}
+	ct, err := pk.Encrypt(7)
+	if err != nil {
+		t.Fatalf("Encrypt() error = %v", err)
+	}
+
+	d, err := shares[0].PartialDecrypt(ct)
+	if err != nil {
+		t.Fatalf("PartialDecrypt() error = %v", err)
+	}
+	// A malicious party tampers with its contribution after proving it.
+	d.Value.Add(d.Value, big.NewInt(1))
+
+	if VerifyDecryptionShare(pk, ct, d) {
+		t.Error("VerifyDecryptionShare() accepted a tampered decryption share")
+	}
+}
+
+func TestThreshold_RejectsDuplicateShareIndex(t *testing.T) {
+	pk, shares, err := GenerateThresholdKeyPair(512, 5, 2)
+	if err != nil {
+		t.Fatalf("GenerateThresholdKeyPair() error = %v", err)
+	}
+	ct, err := pk.Encrypt(7)
+	if err != nil {
+		t.Fatalf("Encrypt() error = %v", err)
+	}
+
+	d1, err := shares[0].PartialDecrypt(ct)
+	if err != nil {
+		t.Fatalf("PartialDecrypt() error = %v", err)
+	}
+	d1Again, err := shares[0].PartialDecrypt(ct)
+	if err != nil {
+		t.Fatalf("PartialDecrypt() error = %v", err)
+	}
+	d2, err := shares[1].PartialDecrypt(ct)
+	if err != nil {
+		t.Fatalf("PartialDecrypt() error = %v", err)
+	}
+
+	// party 1's share submitted twice alongside a genuine third share still
+	// has only two distinct contributors, one short of pk.T+1 = 3.
+	if _, err := Combine(pk, ct, []*DecryptionShare{d1, d1Again, d2}); err == nil {
+		t.Error("Combine() with duplicate share indices did not return an error")
+	}
+}